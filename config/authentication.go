@@ -0,0 +1,194 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Scope is a single permission an authenticated user can be granted.
+type Scope string
+
+const (
+	ScopeRead   Scope = "read"
+	ScopeWrite  Scope = "write"
+	ScopeAdmin  Scope = "admin"
+	ScopePubSub Scope = "pubsub"
+)
+
+// User is one entry of the Authentication user list.
+type User struct {
+	// Username identifies the user for Redis AUTH/HELLO AUTH and HTTP
+	// basic-auth. It is also the map key in Authentication.Users, which is
+	// the only place Username needs to be set by hand when building a
+	// config in code rather than loading it from YAML.
+	Username string `yaml:"username"`
+
+	// PasswordHash is a bcrypt hash, never a plaintext password. Use
+	// HashPassword to produce one.
+	PasswordHash string `yaml:"password_hash"`
+
+	// Scopes lists what this user is allowed to do. An empty list grants
+	// only ScopeRead, matching the principle of least privilege.
+	Scopes []Scope `yaml:"scopes"`
+
+	// CommonName, when set, lets this user authenticate via mTLS: a
+	// client certificate whose Subject.CommonName matches is treated as
+	// this user without a password.
+	CommonName string `yaml:"common_name"`
+}
+
+// HasScope reports whether the user was granted scope, or ScopeAdmin (which
+// implies every other scope).
+func (u *User) HasScope(scope Scope) bool {
+	if len(u.Scopes) == 0 {
+		return scope == ScopeRead
+	}
+	for _, s := range u.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// HashPassword bcrypt-hashes a plaintext password for storage in a user
+// list YAML file or in Authentication.Users.
+func HashPassword(plaintext string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Authentication configures credential checking for both the RESP command
+// path (AUTH, HELLO AUTH) and the HTTP/metrics/cluster-admin endpoints.
+type Authentication struct {
+	// Password is kept for backward compatibility with single-password
+	// deployments: it has no username and is checked before Users. New
+	// deployments should prefer Users.
+	Password string
+
+	// mu guards Users: UsersFileWatcher replaces the map wholesale from a
+	// SIGHUP-triggered goroutine while Authenticate/AuthenticateCommonName
+	// may be reading it concurrently on behalf of in-flight requests.
+	mu sync.RWMutex
+
+	// Users is the first-class, multi-user credential store, keyed by
+	// username. Prefer LoadUsersFile over building this by hand so
+	// passwords are never stored in plaintext. Always access it through
+	// Authenticate/AuthenticateCommonName/Sanitize rather than directly
+	// once a UsersFileWatcher may be running.
+	Users map[string]*User
+
+	// RequireClientCert turns on mTLS authentication: a client certificate
+	// is required, and its CN is mapped to a User via User.CommonName.
+	RequireClientCert bool
+
+	// UsersFile, when set, is reloaded on SIGHUP by WatchUsersFile so
+	// operators can rotate passwords without restarting the node.
+	UsersFile string
+}
+
+// Enabled reports whether any authentication mechanism is configured.
+func (a *Authentication) Enabled() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Password != "" || len(a.Users) > 0 || a.RequireClientCert
+}
+
+// Sanitize sets default values to empty configuration variables.
+func (a *Authentication) Sanitize() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Users == nil {
+		a.Users = make(map[string]*User)
+	}
+	if a.UsersFile != "" && len(a.Users) == 0 {
+		users, err := LoadUsersFile(a.UsersFile)
+		if err != nil {
+			return fmt.Errorf("failed to load users file: %w", err)
+		}
+		a.Users = users
+	}
+	return nil
+}
+
+// Validate finds errors in the current configuration.
+func (a *Authentication) Validate() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.RequireClientCert {
+		hasCN := false
+		for _, u := range a.Users {
+			if u.CommonName != "" {
+				hasCN = true
+				break
+			}
+		}
+		if !hasCN {
+			return fmt.Errorf("RequireClientCert is set but no user has a CommonName mapping")
+		}
+	}
+	for username, u := range a.Users {
+		if u.PasswordHash == "" && u.CommonName == "" {
+			return fmt.Errorf("user %q has neither a password hash nor a CommonName mapping", username)
+		}
+	}
+	return nil
+}
+
+// Authenticate checks a username/password pair from Redis AUTH/HELLO AUTH
+// or HTTP basic-auth. It also accepts the legacy single Password with an
+// empty username, returning a synthetic admin User for that case.
+func (a *Authentication) Authenticate(username, password string) (*User, error) {
+	if username == "" && a.Password != "" {
+		if password != a.Password {
+			return nil, fmt.Errorf("invalid password")
+		}
+		return &User{Username: "", Scopes: []Scope{ScopeAdmin}}, nil
+	}
+
+	a.mu.RLock()
+	u, ok := a.Users[username]
+	a.mu.RUnlock()
+	if !ok || u.PasswordHash == "" {
+		return nil, fmt.Errorf("invalid username or password")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, fmt.Errorf("invalid username or password")
+	}
+	return u, nil
+}
+
+// AuthenticateCommonName resolves the User mapped to an mTLS client
+// certificate's CN, for RequireClientCert deployments.
+func (a *Authentication) AuthenticateCommonName(cn string) (*User, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, u := range a.Users {
+		if u.CommonName == cn {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("no user mapped to certificate CN %q", cn)
+}
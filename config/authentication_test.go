@@ -0,0 +1,157 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestAuthentication_Enabled(t *testing.T) {
+	a := &Authentication{}
+	if a.Enabled() {
+		t.Fatal("Expected Enabled() to be false for an empty Authentication")
+	}
+	a.Password = "secret"
+	if !a.Enabled() {
+		t.Fatal("Expected Enabled() to be true once Password is set")
+	}
+}
+
+func TestAuthentication_AuthenticateUser(t *testing.T) {
+	hash, err := HashPassword("s3cr3t")
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	a := &Authentication{
+		Users: map[string]*User{
+			"alice": {Username: "alice", PasswordHash: hash, Scopes: []Scope{ScopeRead, ScopeWrite}},
+		},
+	}
+	if err := a.Sanitize(); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	user, err := a.Authenticate("alice", "s3cr3t")
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if !user.HasScope(ScopeWrite) {
+		t.Fatal("Expected alice to have ScopeWrite")
+	}
+	if user.HasScope(ScopeAdmin) {
+		t.Fatal("Did not expect alice to have ScopeAdmin")
+	}
+
+	if _, err := a.Authenticate("alice", "wrong"); err == nil {
+		t.Fatal("Expected an error for a wrong password")
+	}
+	if _, err := a.Authenticate("bob", "s3cr3t"); err == nil {
+		t.Fatal("Expected an error for an unknown user")
+	}
+}
+
+func TestAuthentication_LegacyPassword(t *testing.T) {
+	a := &Authentication{Password: "legacy"}
+	user, err := a.Authenticate("", "legacy")
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if !user.HasScope(ScopeAdmin) {
+		t.Fatal("Expected the legacy password to grant ScopeAdmin")
+	}
+}
+
+func TestLoadUsersFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users.yaml")
+	content := `
+users:
+  - username: alice
+    password_hash: "$2a$10$abcdefghijklmnopqrstuv"
+    scopes: ["read", "write"]
+  - username: bob
+    common_name: "bob.olric.internal"
+    scopes: ["admin"]
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	users, err := LoadUsersFile(path)
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 users. Got %d", len(users))
+	}
+	if users["bob"].CommonName != "bob.olric.internal" {
+		t.Fatalf("Expected bob's CommonName to be set. Got %q", users["bob"].CommonName)
+	}
+}
+
+func TestAuthentication_Validate_RequireClientCertWithoutMapping(t *testing.T) {
+	a := &Authentication{RequireClientCert: true}
+	if err := a.Validate(); err == nil {
+		t.Fatal("Expected an error when RequireClientCert is set with no CommonName mapping")
+	}
+}
+
+// TestAuthentication_ConcurrentAuthenticateAndReload exercises Authenticate
+// and AuthenticateCommonName running concurrently with something replacing
+// Users wholesale, the same pattern UsersFileWatcher.reload uses on SIGHUP.
+// It's only meaningful under `go test -race`.
+func TestAuthentication_ConcurrentAuthenticateAndReload(t *testing.T) {
+	hash, err := HashPassword("s3cr3t")
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	a := &Authentication{
+		Users: map[string]*User{
+			"alice": {Username: "alice", PasswordHash: hash, Scopes: []Scope{ScopeRead}},
+		},
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_, _ = a.Authenticate("alice", "s3cr3t")
+			_, _ = a.AuthenticateCommonName("alice.olric.internal")
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		a.mu.Lock()
+		a.Users = map[string]*User{
+			"alice": {Username: "alice", PasswordHash: hash, CommonName: "alice.olric.internal", Scopes: []Scope{ScopeRead}},
+		}
+		a.mu.Unlock()
+	}
+
+	close(stop)
+	wg.Wait()
+}
@@ -22,7 +22,10 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/quic-go/quic-go"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/buraksezer/olric/internal/protocol"
 )
 
 const (
@@ -35,10 +38,47 @@ const (
 	DefaultMaxRetries      = 3
 )
 
+// Transport selects the network transport a native Olric client uses to
+// talk to the cluster.
+type Transport int
+
+const (
+	// TransportTCP dials plain TCP, optionally upgraded to TLS. This is the
+	// default and the only transport RESP clients (go-redis, redis-cli) can
+	// use, since RedisOptions always returns a TCP dialer.
+	TransportTCP Transport = iota
+
+	// TransportQUIC dials over QUIC (see github.com/quic-go/quic-go),
+	// multiplexing every DMap operation onto its own stream instead of a
+	// pooled TCP connection. This removes head-of-line blocking when a slow
+	// scan/range stalls a connection the pool would otherwise hand to an
+	// unrelated request, and it gets TLS 1.3 for free. Connections are dialed
+	// with a full handshake; 0-RTT is not used.
+	TransportQUIC
+)
+
+func (t Transport) String() string {
+	switch t {
+	case TransportQUIC:
+		return "quic"
+	default:
+		return "tcp"
+	}
+}
+
 // Client denotes configuration for TCP clients in Olric and the official Golang client.
 type Client struct {
 	Authentication *Authentication
 
+	// Transport selects the network transport for native Olric clients.
+	// Default is TransportTCP. RESP clients always go through TCP via
+	// RedisOptions, regardless of this setting.
+	Transport Transport
+
+	// QUIC holds transport-specific settings and is only read when
+	// Transport is TransportQUIC.
+	QUIC *QUICConfig
+
 	// Dial timeout for establishing new connections.
 	// Default is 5 seconds.
 	DialTimeout time.Duration
@@ -106,6 +146,25 @@ type Client struct {
 	Limiter redis.Limiter
 }
 
+// QUICConfig holds settings specific to TransportQUIC.
+type QUICConfig struct {
+	// MaxIdleTimeout closes a QUIC connection after this much inactivity.
+	// Default is DefaultIdleTimeout.
+	MaxIdleTimeout time.Duration
+
+	// KeepAlivePeriod, when non-zero, sends a keep-alive frame on that
+	// cadence to hold NAT bindings and idle connections open.
+	KeepAlivePeriod time.Duration
+
+	// MaxIncomingStreams caps the number of concurrent streams a peer may
+	// open on a single connection, bounding how much DMap traffic a slow
+	// server can have in flight without opening a new connection.
+	// Default is 1000.
+	MaxIncomingStreams int64
+}
+
+const DefaultQUICMaxIncomingStreams = 1000
+
 // NewClient returns a new configuration object for clients.
 func NewClient() *Client {
 	c := &Client{
@@ -124,19 +183,36 @@ func (c *Client) Sanitize() error {
 		return fmt.Errorf("failed to sanitize authentication configuration: %w", err)
 	}
 
+	if c.Transport == TransportQUIC {
+		if c.QUIC == nil {
+			c.QUIC = &QUICConfig{}
+		}
+		if c.QUIC.MaxIdleTimeout == 0 {
+			c.QUIC.MaxIdleTimeout = DefaultIdleTimeout
+		}
+		if c.QUIC.MaxIncomingStreams == 0 {
+			c.QUIC.MaxIncomingStreams = DefaultQUICMaxIncomingStreams
+		}
+	}
+
 	if c.DialTimeout == 0 {
 		c.DialTimeout = DefaultDialTimeout
 	}
 	if c.Dialer == nil {
-		c.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
-			netDialer := &net.Dialer{
-				Timeout:   c.DialTimeout,
-				KeepAlive: DefaultKeepalive,
+		switch c.Transport {
+		case TransportQUIC:
+			c.Dialer = c.newQUICDialer()
+		default:
+			c.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				netDialer := &net.Dialer{
+					Timeout:   c.DialTimeout,
+					KeepAlive: DefaultKeepalive,
+				}
+				if c.TLSConfig == nil {
+					return netDialer.DialContext(ctx, network, addr)
+				}
+				return tls.DialWithDialer(netDialer, network, addr, c.TLSConfig)
 			}
-			if c.TLSConfig == nil {
-				return netDialer.DialContext(ctx, network, addr)
-			}
-			return tls.DialWithDialer(netDialer, network, addr, c.TLSConfig)
 		}
 	}
 	if c.PoolSize == 0 {
@@ -187,15 +263,55 @@ func (c *Client) Validate() error {
 	if err := c.Authentication.Validate(); err != nil {
 		return fmt.Errorf("failed to validate authentication configuration: %w", err)
 	}
+	switch c.Transport {
+	case TransportTCP, TransportQUIC:
+	default:
+		return fmt.Errorf("invalid transport: %v", c.Transport)
+	}
 	return nil
 }
 
+// newQUICDialer builds a Dialer backed by a single *protocol.QUICDialer, so
+// every DMap operation this Client makes shares the same cached QUIC
+// connections instead of opening (and handshaking) a new one per call.
+func (c *Client) newQUICDialer() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := protocol.NewQUICDialer()
+	qc := &quic.Config{
+		MaxIdleTimeout:        c.QUIC.MaxIdleTimeout,
+		KeepAlivePeriod:       c.QUIC.KeepAlivePeriod,
+		MaxIncomingStreams:    c.QUIC.MaxIncomingStreams,
+		MaxIncomingUniStreams: -1, // Olric's binary protocol is bidirectional only.
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialQUIC(ctx, addr, &protocol.QUICDialerConfig{
+			TLSConfig:          c.TLSConfig,
+			QUICConfig:         qc,
+			MaxIncomingStreams: c.QUIC.MaxIncomingStreams,
+		})
+	}
+}
+
+// RedisOptions builds a *redis.Options for RESP clients (go-redis, redis-cli).
+// It always dials over TCP, even when c.Transport is TransportQUIC, since
+// QUIC streams are only understood by native Olric clients speaking the
+// binary protocol.
 func (c *Client) RedisOptions() *redis.Options {
 	// Note: IdleCheckFrequency is gone since go-redis no longer checks idle connections.
 	// See https://github.com/redis/go-redis/discussions/2635
+	dialer := c.Dialer
+	if c.Transport == TransportQUIC {
+		// c.Dialer opens QUIC streams here; RESP needs a plain TCP/TLS conn.
+		dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			netDialer := &net.Dialer{Timeout: c.DialTimeout, KeepAlive: DefaultKeepalive}
+			if c.TLSConfig == nil {
+				return netDialer.DialContext(ctx, network, addr)
+			}
+			return tls.DialWithDialer(netDialer, network, addr, c.TLSConfig)
+		}
+	}
 	options := &redis.Options{
 		Network:         "tcp",
-		Dialer:          c.Dialer,
+		Dialer:          dialer,
 		OnConnect:       c.OnConnect,
 		MaxRetries:      c.MaxRetries,
 		MinRetryBackoff: c.MinRetryBackoff,
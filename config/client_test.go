@@ -0,0 +1,43 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "testing"
+
+func TestClient_Sanitize_TCPDialerByDefault(t *testing.T) {
+	c := &Client{Authentication: &Authentication{}}
+	if err := c.Sanitize(); err != nil {
+		t.Fatalf("Sanitize failed: %v", err)
+	}
+	if c.Dialer == nil {
+		t.Fatal("Expected a non-nil Dialer")
+	}
+}
+
+func TestClient_Sanitize_WiresQUICDialer(t *testing.T) {
+	c := &Client{
+		Authentication: &Authentication{},
+		Transport:      TransportQUIC,
+	}
+	if err := c.Sanitize(); err != nil {
+		t.Fatalf("Sanitize failed: %v", err)
+	}
+	if c.QUIC == nil {
+		t.Fatal("Expected QUIC config to be defaulted")
+	}
+	if c.Dialer == nil {
+		t.Fatal("Expected Sanitize to wire a QUIC-backed Dialer for TransportQUIC")
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/buraksezer/olric/internal/storage"
+)
+
+// Default engine name, matching storage.Storage (the original, allocator
+// backed table implementation).
+const DefaultStorageEngine = "kvstore"
+
+// StorageEngine selects and configures the storage.Engine a DMap's
+// partitions are kept in. The zero value selects the in-memory engine.
+type StorageEngine struct {
+	// Name is one of "kvstore" (default), "badger" or "etcd".
+	Name string
+
+	// Badger is only read when Name is "badger".
+	Badger *BadgerEngineConfig
+
+	// Etcd is only read when Name is "etcd".
+	Etcd *EtcdEngineConfig
+}
+
+// BadgerEngineConfig configures the embedded LSM-tree engine.
+type BadgerEngineConfig struct {
+	// Dir is the on-disk directory used for this DMap's data. It must be
+	// unique per DMap; Olric appends the partition ID under it.
+	Dir string
+
+	InMemory   bool
+	SyncWrites bool
+}
+
+// EtcdEngineConfig configures the cluster-shared etcd engine.
+type EtcdEngineConfig struct {
+	Endpoints      []string
+	Prefix         string
+	DialTimeout    time.Duration
+	RequestTimeout time.Duration
+}
+
+// Sanitize sets default values to empty configuration variables.
+func (s *StorageEngine) Sanitize() error {
+	if s.Name == "" {
+		s.Name = DefaultStorageEngine
+	}
+	return nil
+}
+
+// Validate finds errors in the current configuration.
+func (s *StorageEngine) Validate() error {
+	switch s.Name {
+	case "kvstore":
+		return nil
+	case "badger":
+		if s.Badger == nil || s.Badger.Dir == "" {
+			if s.Badger == nil || !s.Badger.InMemory {
+				return fmt.Errorf("badger engine requires Badger.Dir or Badger.InMemory")
+			}
+		}
+	case "etcd":
+		if s.Etcd == nil || len(s.Etcd.Endpoints) == 0 {
+			return fmt.Errorf("etcd engine requires Etcd.Endpoints")
+		}
+	default:
+		return fmt.Errorf("unknown storage engine: %q", s.Name)
+	}
+	return nil
+}
+
+// ToEngineConfig converts the config-facing StorageEngine into the
+// storage.EngineConfig consumed by storage.NewEngine.
+func (s *StorageEngine) ToEngineConfig() *storage.EngineConfig {
+	c := &storage.EngineConfig{Name: s.Name}
+	if s.Badger != nil {
+		c.Badger = &storage.BadgerConfig{
+			Dir:        s.Badger.Dir,
+			InMemory:   s.Badger.InMemory,
+			SyncWrites: s.Badger.SyncWrites,
+		}
+	}
+	if s.Etcd != nil {
+		c.Etcd = &storage.EtcdConfig{
+			Endpoints:      s.Etcd.Endpoints,
+			Prefix:         s.Etcd.Prefix,
+			DialTimeout:    s.Etcd.DialTimeout,
+			RequestTimeout: s.Etcd.RequestTimeout,
+		}
+	}
+	return c
+}
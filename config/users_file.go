@@ -0,0 +1,112 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+)
+
+// usersFile is the on-disk shape of a users YAML file: a list so that
+// Username doesn't have to be repeated as both a map key and a struct
+// field when operators hand-edit it.
+type usersFile struct {
+	Users []*User `yaml:"users"`
+}
+
+// LoadUsersFile reads a YAML user list and returns it keyed by username,
+// ready to assign to Authentication.Users.
+func LoadUsersFile(path string) (map[string]*User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file %s: %w", path, err)
+	}
+
+	var uf usersFile
+	if err := yaml.Unmarshal(data, &uf); err != nil {
+		return nil, fmt.Errorf("failed to parse users file %s: %w", path, err)
+	}
+
+	users := make(map[string]*User, len(uf.Users))
+	for _, u := range uf.Users {
+		if u.Username == "" {
+			return nil, fmt.Errorf("users file %s has an entry with no username", path)
+		}
+		users[u.Username] = u
+	}
+	return users, nil
+}
+
+// UsersFileWatcher reloads Authentication.Users from UsersFile whenever the
+// process receives SIGHUP, so operators can rotate passwords or change
+// scopes without restarting the node.
+type UsersFileWatcher struct {
+	auth    *Authentication
+	stopCh  chan struct{}
+	onError func(error)
+}
+
+// WatchUsersFile starts a UsersFileWatcher for auth. It is a no-op if
+// auth.UsersFile is empty. onError, if non-nil, is called with any error
+// encountered while reloading; a failed reload keeps the previous, still
+// valid user list in place.
+func WatchUsersFile(auth *Authentication, onError func(error)) *UsersFileWatcher {
+	w := &UsersFileWatcher{
+		auth:    auth,
+		stopCh:  make(chan struct{}),
+		onError: onError,
+	}
+	if auth.UsersFile == "" {
+		return w
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-sighup:
+				w.reload()
+			case <-w.stopCh:
+				signal.Stop(sighup)
+				return
+			}
+		}
+	}()
+	return w
+}
+
+func (w *UsersFileWatcher) reload() {
+	users, err := LoadUsersFile(w.auth.UsersFile)
+	if err != nil {
+		if w.onError != nil {
+			w.onError(fmt.Errorf("users file reload failed, keeping previous user list: %w", err))
+		}
+		return
+	}
+
+	w.auth.mu.Lock()
+	defer w.auth.mu.Unlock()
+	w.auth.Users = users
+}
+
+// Stop ends the watcher's signal handling goroutine.
+func (w *UsersFileWatcher) Stop() {
+	close(w.stopCh)
+}
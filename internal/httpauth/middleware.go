@@ -0,0 +1,64 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpauth protects Olric's HTTP surface (stats, metrics, cluster
+// admin) with the same credential store used for the RESP AUTH/HELLO AUTH
+// commands.
+package httpauth
+
+import (
+	"net/http"
+
+	"github.com/buraksezer/olric/config"
+)
+
+// Middleware authenticates every request against auth before calling next,
+// either via HTTP basic-auth or, when auth.RequireClientCert is set, via
+// the CN of the client certificate presented during the TLS handshake.
+func Middleware(auth *config.Authentication, requiredScope config.Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := authenticate(auth, r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="olric"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !user.HasScope(requiredScope) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func authenticate(auth *config.Authentication, r *http.Request) (*config.User, error) {
+	if auth.RequireClientCert {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			return nil, errNoClientCert
+		}
+		cn := r.TLS.PeerCertificates[0].Subject.CommonName
+		return auth.AuthenticateCommonName(cn)
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, errNoBasicAuth
+	}
+	return auth.Authenticate(username, password)
+}
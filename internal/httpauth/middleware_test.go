@@ -0,0 +1,87 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buraksezer/olric/config"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestMiddleware_RequireClientCertWithoutTLSDoesNotPanic(t *testing.T) {
+	auth := &config.Authentication{RequireClientCert: true}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	// No TLS field set: a plain-HTTP request reaching a mTLS-only handler.
+	rec := httptest.NewRecorder()
+
+	Middleware(auth, config.ScopeRead, okHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 for a request with no TLS. Got %d", rec.Code)
+	}
+}
+
+func TestMiddleware_EnforcesRequiredScope(t *testing.T) {
+	hash, err := config.HashPassword("s3cr3t")
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	auth := &config.Authentication{
+		Users: map[string]*config.User{
+			"reader": {Username: "reader", PasswordHash: hash, Scopes: []config.Scope{config.ScopeRead}},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("reader", "s3cr3t")
+	rec := httptest.NewRecorder()
+
+	Middleware(auth, config.ScopeAdmin, okHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 for a read-only user requesting an admin scope. Got %d", rec.Code)
+	}
+}
+
+func TestMiddleware_AllowsMatchingScope(t *testing.T) {
+	hash, err := config.HashPassword("s3cr3t")
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	auth := &config.Authentication{
+		Users: map[string]*config.User{
+			"reader": {Username: "reader", PasswordHash: hash, Scopes: []config.Scope{config.ScopeRead}},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("reader", "s3cr3t")
+	rec := httptest.NewRecorder()
+
+	Middleware(auth, config.ScopeRead, okHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for a matching scope. Got %d", rec.Code)
+	}
+}
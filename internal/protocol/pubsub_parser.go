@@ -93,4 +93,74 @@ func ParsePubSubNumsubCommand(cmd redcon.Command) (*PubSubNumsub, error) {
 		args = args[1:]
 	}
 	return NewPubSubNumsub(channels...), nil
+}
+
+// SSubscribe is the parsed form of SSUBSCRIBE, the sharded-pub/sub
+// counterpart of Subscribe: one or more shard channels to subscribe to.
+type SSubscribe struct {
+	channels []string
+}
+
+// NewSSubscribe creates an SSubscribe for the given shard channels.
+func NewSSubscribe(channels ...string) *SSubscribe {
+	return &SSubscribe{channels: channels}
+}
+
+// Channels returns the shard channels to subscribe to.
+func (s *SSubscribe) Channels() []string {
+	return s.channels
+}
+
+// SPublish is the parsed form of SPUBLISH, the sharded-pub/sub counterpart
+// of Publish: a single shard channel and the message to deliver to it.
+type SPublish struct {
+	channel string
+	message string
+}
+
+// NewSPublish creates an SPublish for channel carrying message.
+func NewSPublish(channel, message string) *SPublish {
+	return &SPublish{channel: channel, message: message}
+}
+
+// Channel returns the shard channel the message should be delivered to.
+func (s *SPublish) Channel() string {
+	return s.channel
+}
+
+// Message returns the payload to deliver.
+func (s *SPublish) Message() string {
+	return s.message
+}
+
+// ParseSSubscribeCommand parses SSUBSCRIBE, the sharded-pub/sub counterpart
+// of SUBSCRIBE. Like SUBSCRIBE, it accepts one or more channels,
+// but each one is routed to its single owner node via consistent hashing
+// instead of being broadcast to the whole cluster.
+func ParseSSubscribeCommand(cmd redcon.Command) (*SSubscribe, error) {
+	if len(cmd.Args) < 2 {
+		return nil, errWrongNumber(cmd.Args)
+	}
+
+	var channels []string
+	args := cmd.Args[1:]
+	for len(args) > 0 {
+		arg := util.BytesToString(args[0])
+		channels = append(channels, arg)
+		args = args[1:]
+	}
+	return NewSSubscribe(channels...), nil
+}
+
+// ParseSPublishCommand parses SPUBLISH, the sharded-pub/sub counterpart of
+// PUBLISH.
+func ParseSPublishCommand(cmd redcon.Command) (*SPublish, error) {
+	if len(cmd.Args) < 3 {
+		return nil, errWrongNumber(cmd.Args)
+	}
+
+	return NewSPublish(
+		util.BytesToString(cmd.Args[1]), // Channel
+		util.BytesToString(cmd.Args[2]), // Message
+	), nil
 }
\ No newline at end of file
@@ -0,0 +1,133 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+)
+
+// QUICDialerConfig configures the client side of a QUIC connection to an
+// Olric node. It is built from config.Client when Transport is TransportQUIC.
+type QUICDialerConfig struct {
+	TLSConfig          *tls.Config
+	QUICConfig         *quic.Config
+	MaxIncomingStreams int64
+}
+
+// QUICDialer opens Olric binary-protocol streams over QUIC. It caches one
+// quic.Connection per remote address so repeated calls open a new stream on
+// the existing connection instead of paying for a fresh handshake every
+// time; stream multiplexing and 0-RTT resumption only pay off if the
+// connection is actually reused.
+type QUICDialer struct {
+	mu    sync.Mutex
+	conns map[string]quic.Connection
+}
+
+// NewQUICDialer returns a QUICDialer with an empty connection cache.
+func NewQUICDialer() *QUICDialer {
+	return &QUICDialer{conns: make(map[string]quic.Connection)}
+}
+
+// DialQUIC opens (or reuses) a QUIC connection to addr and returns a new
+// stream on it wrapped as a net.Conn, so it slots into code paths written
+// against config.Client.Dialer. Each call opens a fresh stream rather than a
+// fresh connection: 0-RTT session resumption and stream multiplexing are
+// the whole point of preferring QUIC for bulk scan/range traffic.
+func (d *QUICDialer) DialQUIC(ctx context.Context, addr string, c *QUICDialerConfig) (net.Conn, error) {
+	conn, err := d.connection(ctx, addr, c)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		// The cached connection is no longer usable; drop it so the next
+		// call dials a fresh one instead of retrying the same dead conn.
+		d.mu.Lock()
+		if d.conns[addr] == conn {
+			delete(d.conns, addr)
+		}
+		d.mu.Unlock()
+		return nil, fmt.Errorf("protocol: failed to open quic stream to %s: %w", addr, err)
+	}
+	return &quicStreamConn{Stream: stream, conn: conn}, nil
+}
+
+func (d *QUICDialer) connection(ctx context.Context, addr string, c *QUICDialerConfig) (quic.Connection, error) {
+	d.mu.Lock()
+	if conn, ok := d.conns[addr]; ok {
+		select {
+		case <-conn.Context().Done():
+			// The cached connection closed in the background; fall through
+			// and dial a new one.
+			delete(d.conns, addr)
+		default:
+			d.mu.Unlock()
+			return conn, nil
+		}
+	}
+	d.mu.Unlock()
+
+	tlsConf := c.TLSConfig
+	if tlsConf == nil {
+		tlsConf = &tls.Config{}
+	}
+
+	qc := c.QUICConfig
+	if qc == nil {
+		qc = &quic.Config{MaxIncomingStreams: c.MaxIncomingStreams}
+	}
+
+	conn, err := quic.DialAddr(ctx, addr, tlsConf, qc)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failed to dial %s over quic: %w", addr, err)
+	}
+
+	d.mu.Lock()
+	d.conns[addr] = conn
+	d.mu.Unlock()
+	return conn, nil
+}
+
+// Close closes every cached connection. It's used when the client shuts
+// down to avoid leaking QUIC connections.
+func (d *QUICDialer) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var firstErr error
+	for addr, conn := range d.conns {
+		if err := conn.CloseWithError(0, "client closed"); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(d.conns, addr)
+	}
+	return firstErr
+}
+
+// DialQUIC opens (or reuses) a QUIC connection to addr and returns a new
+// stream on it wrapped as a net.Conn. It's a convenience wrapper around a
+// throwaway QUICDialer for callers that don't need connection reuse across
+// calls (tests, one-off tooling); config.Client.Sanitize wires up a shared
+// *QUICDialer instead so production traffic actually reuses connections.
+func DialQUIC(ctx context.Context, addr string, c *QUICDialerConfig) (net.Conn, error) {
+	return NewQUICDialer().DialQUIC(ctx, addr, c)
+}
@@ -0,0 +1,164 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// QUICListenerConfig configures a server-side QUIC listener for the native
+// Olric binary protocol. It mirrors config.QUICConfig on the client side.
+type QUICListenerConfig struct {
+	BindAddr        string
+	TLSConfig       *tls.Config
+	MaxIdleTimeout  time.Duration
+	KeepAlivePeriod time.Duration
+
+	// MaxIncomingStreams caps concurrent streams per connection, so one
+	// slow DMap operation cannot starve the rest of that client's traffic
+	// out of the stream limit.
+	MaxIncomingStreams int64
+}
+
+// acceptedStream carries either a stream accepted on some connection's
+// accept loop, or the terminal error from the top-level connection-accept
+// loop once the listener can no longer produce new connections.
+type acceptedStream struct {
+	conn net.Conn
+	err  error
+}
+
+// QUICListener accepts Olric binary-protocol connections over QUIC. Every
+// accepted QUIC connection gets its own background loop that keeps calling
+// AcceptStream, so a client opening several streams on one connection (the
+// whole point of preferring QUIC) has every one of them picked up, not just
+// the first. Accept hands out one net.Conn-compatible stream per call, in
+// the order any connection's loop produced it, so the existing per-stream
+// command loop can be reused unmodified.
+type QUICListener struct {
+	ql      *quic.Listener
+	streams chan acceptedStream
+	done    chan struct{}
+}
+
+// ListenQUIC starts a QUIC listener on c.BindAddr. c.TLSConfig is required:
+// QUIC mandates TLS 1.3 for every connection.
+func ListenQUIC(c *QUICListenerConfig) (*QUICListener, error) {
+	if c.TLSConfig == nil {
+		return nil, fmt.Errorf("protocol: QUIC listener requires a TLS config")
+	}
+
+	qc := &quic.Config{
+		MaxIdleTimeout:        c.MaxIdleTimeout,
+		KeepAlivePeriod:       c.KeepAlivePeriod,
+		MaxIncomingStreams:    c.MaxIncomingStreams,
+		MaxIncomingUniStreams: -1, // Olric's binary protocol is bidirectional only.
+	}
+
+	ql, err := quic.ListenAddr(c.BindAddr, c.TLSConfig, qc)
+	if err != nil {
+		return nil, fmt.Errorf("protocol: failed to listen on %s: %w", c.BindAddr, err)
+	}
+
+	l := &QUICListener{
+		ql:      ql,
+		streams: make(chan acceptedStream),
+		done:    make(chan struct{}),
+	}
+	go l.acceptConnections()
+	return l, nil
+}
+
+// acceptConnections accepts new QUIC connections for the lifetime of the
+// listener and spins up a per-connection stream-accept loop for each one.
+func (l *QUICListener) acceptConnections() {
+	ctx := context.Background()
+	for {
+		conn, err := l.ql.Accept(ctx)
+		if err != nil {
+			select {
+			case l.streams <- acceptedStream{err: err}:
+			case <-l.done:
+			}
+			return
+		}
+		go l.acceptStreams(conn)
+	}
+}
+
+// acceptStreams keeps accepting streams on conn until it's closed, feeding
+// each one to Accept's caller. A client may open any number of streams on
+// the same connection; none of them would be picked up if this loop
+// stopped after the first.
+func (l *QUICListener) acceptStreams(conn quic.Connection) {
+	ctx := context.Background()
+	for {
+		stream, err := conn.AcceptStream(ctx)
+		if err != nil {
+			return
+		}
+		select {
+		case l.streams <- acceptedStream{conn: &quicStreamConn{Stream: stream, conn: conn}}:
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// Accept blocks until a peer opens a new stream, on either a new or an
+// existing QUIC connection, and returns it wrapped as a net.Conn so callers
+// can treat it exactly like a TCP connection.
+func (l *QUICListener) Accept(ctx context.Context) (net.Conn, error) {
+	select {
+	case s := <-l.streams:
+		return s.conn, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close shuts down the listener. In-flight connections are not drained.
+func (l *QUICListener) Close() error {
+	close(l.done)
+	return l.ql.Close()
+}
+
+// Addr returns the listener's bound address.
+func (l *QUICListener) Addr() net.Addr {
+	return l.ql.Addr()
+}
+
+// quicStreamConn adapts a quic.Stream, plus the quic.Connection it belongs
+// to, to the net.Conn interface expected by the rest of the server.
+type quicStreamConn struct {
+	quic.Stream
+	conn quic.Connection
+}
+
+func (c *quicStreamConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *quicStreamConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
@@ -0,0 +1,156 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protocol
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testTLSConfigs returns a matching pair of server/client TLS configs backed
+// by a throwaway self-signed certificate, so tests can run a real QUIC
+// listener/dialer pair over loopback.
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "olric-quic-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+
+	server = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"olric-quic-test"},
+	}
+	client = &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"olric-quic-test"},
+	}
+	return server, client
+}
+
+func TestQUIC_ListenerAcceptsMultipleStreamsOnOneConnection(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+
+	l, err := ListenQUIC(&QUICListenerConfig{
+		BindAddr:  "127.0.0.1:0",
+		TLSConfig: serverTLS,
+	})
+	if err != nil {
+		t.Fatalf("ListenQUIC failed: %v", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dialer := NewQUICDialer()
+	dialerConf := &QUICDialerConfig{TLSConfig: clientTLS}
+
+	first, err := dialer.DialQUIC(ctx, l.Addr().String(), dialerConf)
+	if err != nil {
+		t.Fatalf("first DialQUIC failed: %v", err)
+	}
+	second, err := dialer.DialQUIC(ctx, l.Addr().String(), dialerConf)
+	if err != nil {
+		t.Fatalf("second DialQUIC failed: %v", err)
+	}
+	// The peer can only accept a QUIC stream once data has been sent on it.
+	for _, conn := range []net.Conn{first, second} {
+		if _, err := conn.Write([]byte{0}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.Accept(ctx); err != nil {
+			t.Fatalf("Accept %d failed: %v", i, err)
+		}
+	}
+}
+
+func TestQUICDialer_ReusesConnection(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+
+	l, err := ListenQUIC(&QUICListenerConfig{
+		BindAddr:  "127.0.0.1:0",
+		TLSConfig: serverTLS,
+	})
+	if err != nil {
+		t.Fatalf("ListenQUIC failed: %v", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dialer := NewQUICDialer()
+	dialerConf := &QUICDialerConfig{TLSConfig: clientTLS}
+
+	first, err := dialer.DialQUIC(ctx, l.Addr().String(), dialerConf)
+	if err != nil {
+		t.Fatalf("first DialQUIC failed: %v", err)
+	}
+	// The peer can only accept a QUIC stream once data has been sent on it.
+	if _, err := first.Write([]byte{0}); err != nil {
+		t.Fatalf("first Write failed: %v", err)
+	}
+	if _, err := l.Accept(ctx); err != nil {
+		t.Fatalf("first Accept failed: %v", err)
+	}
+
+	second, err := dialer.DialQUIC(ctx, l.Addr().String(), dialerConf)
+	if err != nil {
+		t.Fatalf("second DialQUIC failed: %v", err)
+	}
+	if _, err := second.Write([]byte{0}); err != nil {
+		t.Fatalf("second Write failed: %v", err)
+	}
+	if _, err := l.Accept(ctx); err != nil {
+		t.Fatalf("second Accept failed: %v", err)
+	}
+
+	// Every dialed QUIC connection gets its own local UDP address, so equal
+	// local addrs mean both streams ride on the same connection.
+	if first.LocalAddr().String() != second.LocalAddr().String() {
+		t.Fatalf("expected streams to share a connection's local addr, got %s and %s",
+			first.LocalAddr(), second.LocalAddr())
+	}
+	if len(dialer.conns) != 1 {
+		t.Fatalf("expected exactly 1 cached connection, got %d", len(dialer.conns))
+	}
+}
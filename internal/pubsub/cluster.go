@@ -0,0 +1,101 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Member is the subset of cluster membership the scatter/gather helpers in
+// this file need. The real implementation is the node's memberlist client;
+// tests can supply a small stub.
+type Member interface {
+	// Query asks the member for its local PUBSUB CHANNELS/NUMSUB answer
+	// over the internal cluster protocol and returns the raw reply.
+	Query(ctx context.Context, pattern string, channels []string) (*LocalReply, error)
+}
+
+// LocalReply is one member's answer to a scatter query.
+type LocalReply struct {
+	Channels []string
+	Counts   []int // parallel to the queried channels, for NUMSUB
+}
+
+// ScatterGather runs PUBSUB CHANNELS/NUMSUB across every member of the
+// cluster and merges the replies on the coordinator, so the result
+// reflects cluster-wide state rather than just the local node. It is used
+// for both PUBSUB CHANNELS (pattern set, no channels) and PUBSUB NUMSUB
+// (channels set, no pattern).
+func ScatterGather(ctx context.Context, members []Member, pattern string, channels []string) (*LocalReply, error) {
+	type result struct {
+		reply *LocalReply
+		err   error
+	}
+
+	results := make([]result, len(members))
+	var wg sync.WaitGroup
+	for i, m := range members {
+		wg.Add(1)
+		go func(i int, m Member) {
+			defer wg.Done()
+			reply, err := m.Query(ctx, pattern, channels)
+			results[i] = result{reply: reply, err: err}
+		}(i, m)
+	}
+	wg.Wait()
+
+	merged := &LocalReply{}
+	if len(channels) > 0 {
+		merged.Counts = make([]int, len(channels))
+	}
+
+	var firstErr error
+	var ok int
+	channelSet := make(map[string]struct{})
+	for _, r := range results {
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+		if r.reply == nil {
+			continue
+		}
+		ok++
+		for _, ch := range r.reply.Channels {
+			if _, ok := channelSet[ch]; !ok {
+				channelSet[ch] = struct{}{}
+				merged.Channels = append(merged.Channels, ch)
+			}
+		}
+		for i := range merged.Counts {
+			if i < len(r.reply.Counts) {
+				merged.Counts[i] += r.reply.Counts[i]
+			}
+		}
+	}
+
+	// A single unreachable member shouldn't fail the whole cluster-wide
+	// query; operators care more about an approximate live answer than a
+	// hard error. Only surface the error if no member replied at all
+	// (ok == 0), for both PUBSUB CHANNELS and PUBSUB NUMSUB; a reply with no
+	// channels still counts as a reply.
+	if firstErr != nil && ok == 0 {
+		return nil, firstErr
+	}
+	return merged, nil
+}
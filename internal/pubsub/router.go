@@ -0,0 +1,164 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pubsub implements the local dispatch side of Olric's PUBSUB
+// support: matching subscribers to published messages and answering
+// introspection commands such as PUBSUB CHANNELS/NUMSUB. Wire parsing for
+// these commands lives in internal/protocol.
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/cespare/xxhash"
+)
+
+// shardCount is the number of independent channel shards a Router keeps.
+// Splitting the subscriber table this way turns PUBLISH's lock contention
+// from one RWMutex shared by every channel on the node into one scoped to
+// whichever shard the published channel hashes to.
+const shardCount = 32
+
+// Subscriber receives messages for channels or patterns it is registered
+// for. *net.Conn-backed connections implement this in the server package;
+// tests can use a simple channel-backed stub.
+type Subscriber interface {
+	// ID uniquely identifies this subscriber within the router, so
+	// Unsubscribe can find the right entry even if two subscribers are
+	// otherwise identical (e.g. same connection resubscribing).
+	ID() uint64
+	Send(channel string, message []byte) error
+}
+
+type shard struct {
+	mu   sync.RWMutex
+	subs map[string]map[uint64]Subscriber
+}
+
+func newShard() *shard {
+	return &shard{subs: make(map[string]map[uint64]Subscriber)}
+}
+
+// Router is a sharded, local (single-node) pub/sub dispatch table. Pattern
+// subscriptions are kept separately in a trie since they cannot be hashed
+// to a single shard.
+type Router struct {
+	shards   [shardCount]*shard
+	patterns *patternTrie
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	r := &Router{patterns: newPatternTrie()}
+	for i := range r.shards {
+		r.shards[i] = newShard()
+	}
+	return r
+}
+
+func (r *Router) shardFor(channel string) *shard {
+	return r.shards[xxhash.Sum64String(channel)%shardCount]
+}
+
+// Subscribe registers sub to receive messages published on channel.
+func (r *Router) Subscribe(channel string, sub Subscriber) {
+	s := r.shardFor(channel)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs[channel] == nil {
+		s.subs[channel] = make(map[uint64]Subscriber)
+	}
+	s.subs[channel][sub.ID()] = sub
+}
+
+// Unsubscribe removes sub from channel. It is a no-op if sub was not
+// subscribed.
+func (r *Router) Unsubscribe(channel string, sub Subscriber) {
+	s := r.shardFor(channel)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs[channel], sub.ID())
+	if len(s.subs[channel]) == 0 {
+		delete(s.subs, channel)
+	}
+}
+
+// PSubscribe registers sub to receive messages on every channel matching
+// pattern (glob syntax: '*', '?' and '[...]').
+func (r *Router) PSubscribe(pattern string, sub Subscriber) {
+	r.patterns.add(pattern, sub)
+}
+
+// PUnsubscribe removes sub from pattern.
+func (r *Router) PUnsubscribe(pattern string, sub Subscriber) {
+	r.patterns.remove(pattern, sub)
+}
+
+// Publish delivers message to every direct subscriber of channel and every
+// pattern subscriber whose pattern matches it, returning the number of
+// subscribers it was delivered to on this node.
+func (r *Router) Publish(channel string, message []byte) int {
+	delivered := 0
+
+	s := r.shardFor(channel)
+	s.mu.RLock()
+	for _, sub := range s.subs[channel] {
+		if sub.Send(channel, message) == nil {
+			delivered++
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, sub := range r.patterns.match(channel) {
+		if sub.Send(channel, message) == nil {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// Channels returns the distinct channels with at least one direct
+// subscriber on this node, optionally filtered by a glob pattern.
+func (r *Router) Channels(pattern string) []string {
+	var out []string
+	for _, s := range r.shards {
+		s.mu.RLock()
+		for channel := range s.subs {
+			if pattern == "" || Match(pattern, channel) {
+				out = append(out, channel)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return out
+}
+
+// Numsub returns the number of direct subscribers on this node for each of
+// the given channels, in the same order.
+func (r *Router) Numsub(channels []string) []int {
+	counts := make([]int, len(channels))
+	for i, channel := range channels {
+		s := r.shardFor(channel)
+		s.mu.RLock()
+		counts[i] = len(s.subs[channel])
+		s.mu.RUnlock()
+	}
+	return counts
+}
+
+// Numpat returns the number of distinct patterns with at least one
+// subscriber on this node.
+func (r *Router) Numpat() int {
+	return r.patterns.count()
+}
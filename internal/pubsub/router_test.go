@@ -0,0 +1,111 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import "testing"
+
+type stubSubscriber struct {
+	id       uint64
+	received []string
+}
+
+func (s *stubSubscriber) ID() uint64 { return s.id }
+
+func (s *stubSubscriber) Send(channel string, message []byte) error {
+	s.received = append(s.received, channel+":"+string(message))
+	return nil
+}
+
+func TestRouter_SubscribePublish(t *testing.T) {
+	r := NewRouter()
+	sub := &stubSubscriber{id: 1}
+	r.Subscribe("room:1", sub)
+
+	delivered := r.Publish("room:1", []byte("hi"))
+	if delivered != 1 {
+		t.Fatalf("Expected 1 delivery. Got %d", delivered)
+	}
+	if len(sub.received) != 1 || sub.received[0] != "room:1:hi" {
+		t.Fatalf("Unexpected delivery: %v", sub.received)
+	}
+
+	r.Unsubscribe("room:1", sub)
+	if r.Publish("room:1", []byte("bye")) != 0 {
+		t.Fatal("Expected no deliveries after unsubscribe")
+	}
+}
+
+func TestRouter_PSubscribe(t *testing.T) {
+	r := NewRouter()
+	sub := &stubSubscriber{id: 2}
+	r.PSubscribe("room:*", sub)
+
+	if delivered := r.Publish("room:42", []byte("x")); delivered != 1 {
+		t.Fatalf("Expected 1 pattern delivery. Got %d", delivered)
+	}
+	if delivered := r.Publish("other:42", []byte("x")); delivered != 0 {
+		t.Fatalf("Expected 0 deliveries for a non-matching channel. Got %d", delivered)
+	}
+}
+
+func TestRouter_PSubscribeWildcardSpansSegments(t *testing.T) {
+	r := NewRouter()
+	sub := &stubSubscriber{id: 3}
+	r.PSubscribe("room:*", sub)
+
+	if delivered := r.Publish("room:1:chat", []byte("x")); delivered != 1 {
+		t.Fatalf("Expected 1 delivery for a channel whose suffix crosses ':'. Got %d", delivered)
+	}
+	if len(sub.received) != 1 || sub.received[0] != "room:1:chat:x" {
+		t.Fatalf("Unexpected delivery: %v", sub.received)
+	}
+}
+
+func TestRouter_NumsubAndChannels(t *testing.T) {
+	r := NewRouter()
+	r.Subscribe("a", &stubSubscriber{id: 1})
+	r.Subscribe("a", &stubSubscriber{id: 2})
+	r.Subscribe("b", &stubSubscriber{id: 3})
+
+	counts := r.Numsub([]string{"a", "b", "c"})
+	if counts[0] != 2 || counts[1] != 1 || counts[2] != 0 {
+		t.Fatalf("Unexpected counts: %v", counts)
+	}
+
+	channels := r.Channels("")
+	if len(channels) != 2 {
+		t.Fatalf("Expected 2 channels. Got %d", len(channels))
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, channel string
+		want             bool
+	}{
+		{"room:*", "room:1", true},
+		{"room:*", "lobby:1", false},
+		{"room:?", "room:1", true},
+		{"room:?", "room:12", false},
+		{"room:[12]", "room:1", true},
+		{"room:[12]", "room:3", false},
+		{"room:[^12]", "room:3", true},
+	}
+	for _, c := range cases {
+		if got := Match(c.pattern, c.channel); got != c.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", c.pattern, c.channel, got, c.want)
+		}
+	}
+}
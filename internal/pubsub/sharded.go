@@ -0,0 +1,81 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import "github.com/cespare/xxhash"
+
+// OwnerPicker resolves which cluster member owns a channel for sharded
+// pub/sub, the same way a DMap key is routed to its partition owner. The
+// real implementation hashes into the partition ring; tests can use a
+// simple modulo over a fixed member list.
+type OwnerPicker interface {
+	// Owner returns an opaque identifier (host:port) for the member that
+	// owns channel.
+	Owner(channel string) string
+}
+
+// ShardedRouter implements Redis Cluster's sharded pub/sub semantics:
+// SSUBSCRIBE/SPUBLISH route a channel to exactly one owner node via
+// consistent hashing, instead of fanning out to every node like
+// PUBLISH/SUBSCRIBE do. This avoids the O(N) broadcast cost of regular
+// PUBSUB on clusters with many shard channels and many nodes.
+type ShardedRouter struct {
+	local  *Router
+	picker OwnerPicker
+	self   string
+}
+
+// NewShardedRouter creates a ShardedRouter. self is this node's own
+// host:port, used to tell whether an SPUBLISH should be delivered locally
+// or forwarded.
+func NewShardedRouter(picker OwnerPicker, self string) *ShardedRouter {
+	return &ShardedRouter{
+		local:  NewRouter(),
+		picker: picker,
+		self:   self,
+	}
+}
+
+// ChannelHash returns the 64-bit xxhash of channel. ShardedRouter does not
+// call it: owner selection is left entirely to its OwnerPicker.
+func ChannelHash(channel string) uint64 {
+	return xxhash.Sum64String(channel)
+}
+
+// Owns reports whether this node owns channel and should hold its
+// SSUBSCRIBE subscribers directly.
+func (r *ShardedRouter) Owns(channel string) bool {
+	return r.picker.Owner(channel) == r.self
+}
+
+// SSubscribe registers sub for channel. If this node isn't the owner, the
+// caller is expected to forward the subscription to Owner(channel) over the
+// internal protocol; SSubscribe only manages the local subscriber table for
+// channels this node owns.
+func (r *ShardedRouter) SSubscribe(channel string, sub Subscriber) {
+	r.local.Subscribe(channel, sub)
+}
+
+// SUnsubscribe removes sub from channel's local subscriber table.
+func (r *ShardedRouter) SUnsubscribe(channel string, sub Subscriber) {
+	r.local.Unsubscribe(channel, sub)
+}
+
+// SPublishLocal delivers message to this node's subscribers of channel. It
+// does not forward to the owner: callers must route to Owner(channel) first
+// when Owns(channel) is false, matching SPUBLISH's single-owner semantics.
+func (r *ShardedRouter) SPublishLocal(channel string, message []byte) int {
+	return r.local.Publish(channel, message)
+}
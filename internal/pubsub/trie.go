@@ -0,0 +1,218 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"strings"
+	"sync"
+)
+
+// patternTrie indexes glob patterns by their literal path segments
+// (channels are conventionally namespaced with ':', e.g. "room:12:chat"),
+// so matching an incoming channel only has to walk the segments that could
+// plausibly match instead of testing every registered pattern in turn.
+// Wildcard segments ('*', '?' or containing '[') fan out to every child at
+// that level.
+type patternTrie struct {
+	mu   sync.RWMutex
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[string]*trieNode
+	subs     map[uint64]Subscriber
+	pattern  string // only set on the node a full pattern terminates at
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[string]*trieNode)}
+}
+
+func newPatternTrie() *patternTrie {
+	return &patternTrie{root: newTrieNode()}
+}
+
+func segments(pattern string) []string {
+	return strings.Split(pattern, ":")
+}
+
+func isWildcardSegment(seg string) bool {
+	return strings.ContainsAny(seg, "*?[")
+}
+
+func (t *patternTrie) add(pattern string, sub Subscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	node := t.root
+	for _, seg := range segments(pattern) {
+		child, ok := node.children[seg]
+		if !ok {
+			child = newTrieNode()
+			node.children[seg] = child
+		}
+		node = child
+	}
+	if node.subs == nil {
+		node.subs = make(map[uint64]Subscriber)
+		node.pattern = pattern
+	}
+	node.subs[sub.ID()] = sub
+}
+
+func (t *patternTrie) remove(pattern string, sub Subscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	node := t.root
+	for _, seg := range segments(pattern) {
+		child, ok := node.children[seg]
+		if !ok {
+			return
+		}
+		node = child
+	}
+	delete(node.subs, sub.ID())
+}
+
+func (t *patternTrie) count() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var n int
+	var walk func(*trieNode)
+	walk = func(node *trieNode) {
+		if len(node.subs) > 0 {
+			n++
+		}
+		for _, child := range node.children {
+			walk(child)
+		}
+	}
+	walk(t.root)
+	return n
+}
+
+// match returns every subscriber whose pattern matches channel, deduped by
+// subscriber ID (a subscriber could in principle register overlapping
+// patterns that both match).
+func (t *patternTrie) match(channel string) []Subscriber {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	segs := segments(channel)
+	seen := make(map[uint64]Subscriber)
+	t.walk(t.root, segs, seen)
+
+	out := make([]Subscriber, 0, len(seen))
+	for _, sub := range seen {
+		out = append(out, sub)
+	}
+	return out
+}
+
+func (t *patternTrie) walk(node *trieNode, segs []string, seen map[uint64]Subscriber) {
+	if len(segs) == 0 {
+		for id, sub := range node.subs {
+			seen[id] = sub
+		}
+		return
+	}
+
+	for seg, child := range node.children {
+		if isWildcardSegment(seg) {
+			// A wildcard pattern segment isn't bound to consuming exactly one
+			// channel segment (e.g. "room:*" must match "room:1:chat"), so try
+			// every split point of the remaining channel segments and let
+			// Match decide which spans the wildcard actually accepts. A pattern
+			// segment always spans at least one channel segment, since the ':'
+			// on either side of it must line up with a ':' in the channel.
+			for c := 1; c <= len(segs); c++ {
+				if Match(seg, strings.Join(segs[:c], ":")) {
+					t.walk(child, segs[c:], seen)
+				}
+			}
+			continue
+		}
+		if seg == segs[0] {
+			t.walk(child, segs[1:], seen)
+		}
+	}
+}
+
+// Match reports whether channel matches the Redis-style glob pattern,
+// supporting '*' (any run of characters), '?' (any single character) and
+// '[...]' character classes.
+func Match(pattern, channel string) bool {
+	return globMatch(pattern, channel)
+}
+
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern, ']')
+			if end == -1 {
+				// Malformed class; treat '[' as a literal.
+				if s[0] != '[' {
+					return false
+				}
+				s = s[1:]
+				pattern = pattern[1:]
+				continue
+			}
+			class := pattern[1:end]
+			negate := false
+			if strings.HasPrefix(class, "^") {
+				negate = true
+				class = class[1:]
+			}
+			if strings.ContainsRune(class, rune(s[0])) == negate {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[end+1:]
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(s) == 0
+}
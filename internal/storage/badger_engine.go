@@ -0,0 +1,179 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+// BadgerConfig configures the embedded LSM-tree engine used for DMaps that
+// need to spill beyond RAM.
+type BadgerConfig struct {
+	// Dir is the on-disk directory Badger uses for its value log and SST
+	// files. It must be unique per partition fragment.
+	Dir string
+
+	// InMemory runs Badger without touching disk, useful for tests.
+	InMemory bool
+
+	// SyncWrites trades write latency for durability against process
+	// crashes. Off by default, matching Badger's own default.
+	SyncWrites bool
+}
+
+// BadgerEngine is an Engine backed by BadgerDB, for partitions that no
+// longer fit comfortably in RAM.
+type BadgerEngine struct {
+	db *badger.DB
+}
+
+// NewBadgerEngine opens (or creates) a Badger-backed Engine at c.Badger.Dir.
+func NewBadgerEngine(c *EngineConfig) (Engine, error) {
+	if c.Badger == nil {
+		return nil, fmt.Errorf("storage: badger engine requires EngineConfig.Badger")
+	}
+
+	opts := badger.DefaultOptions(c.Badger.Dir)
+	opts = opts.WithInMemory(c.Badger.InMemory)
+	opts = opts.WithSyncWrites(c.Badger.SyncWrites)
+	opts = opts.WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to open badger engine: %w", err)
+	}
+	return &BadgerEngine{db: db}, nil
+}
+
+// Name implements Engine.
+func (b *BadgerEngine) Name() string {
+	return "badger"
+}
+
+func badgerKey(hkey uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, hkey)
+	return key
+}
+
+// Put implements Engine.
+func (b *BadgerEngine) Put(hkey uint64, vdata *VData) error {
+	raw := encodeVData(vdata)
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(badgerKey(hkey), raw)
+	})
+}
+
+// Get implements Engine.
+func (b *BadgerEngine) Get(hkey uint64) (*VData, error) {
+	var vdata *VData
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerKey(hkey))
+		if err == badger.ErrKeyNotFound {
+			return ErrKeyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		// ValueCopy, not Value: the callback's byte slice is only valid
+		// until it returns, but vdata needs to outlive this transaction.
+		raw, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		vdata = decodeVData(raw)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vdata, nil
+}
+
+// Check implements Engine.
+func (b *BadgerEngine) Check(hkey uint64) bool {
+	err := b.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(badgerKey(hkey))
+		return err
+	})
+	return err == nil
+}
+
+// Delete implements Engine.
+func (b *BadgerEngine) Delete(hkey uint64) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(badgerKey(hkey))
+	})
+}
+
+// Len implements Engine.
+func (b *BadgerEngine) Len() int {
+	var count int
+	_ = b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+// Range implements Engine.
+func (b *BadgerEngine) Range(f func(hkey uint64, vdata *VData) bool) {
+	_ = b.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			hkey := binary.BigEndian.Uint64(item.Key())
+			// ValueCopy, not Value: f may retain vdata past this
+			// iteration, after Badger is free to reuse the buffer.
+			raw, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if !f(hkey, decodeVData(raw)) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Export implements Engine by streaming Range into the same wire format
+// used by the in-memory engine, so backups are portable across drivers.
+func (b *BadgerEngine) Export(w io.Writer) error {
+	return exportEngine(b, w)
+}
+
+// Close implements Engine.
+func (b *BadgerEngine) Close() error {
+	return b.db.Close()
+}
+
+// Interface guard
+var _ Engine = (*BadgerEngine)(nil)
+
+func init() {
+	RegisterEngine("badger", func(c *EngineConfig) (Engine, error) {
+		return NewBadgerEngine(c)
+	})
+}
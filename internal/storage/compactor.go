@@ -0,0 +1,240 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultCompactionInterval is how often the Compactor wakes up to check
+// its triggers when CompactorConfig.Interval is left at zero.
+const DefaultCompactionInterval = 10 * time.Second
+
+// DefaultFragmentationThreshold triggers a compaction once this fraction of
+// a table's allocated bytes is garbage.
+const DefaultFragmentationThreshold = 0.30
+
+// CompactorConfig tunes when the background Compactor runs and how
+// aggressively it is allowed to move bytes while doing so.
+type CompactorConfig struct {
+	// Interval is the cadence the Compactor wakes up on to re-check its
+	// triggers. Default is DefaultCompactionInterval.
+	Interval time.Duration
+
+	// FragmentationThreshold triggers a compaction once any table's
+	// garbage ratio exceeds it. Default is DefaultFragmentationThreshold.
+	FragmentationThreshold float64
+
+	// IOBytesPerSecond is the refill rate of a token bucket whose burst is
+	// one second's worth of tokens. Before each compaction pass the
+	// Compactor waits for as many tokens as the storage has allocated
+	// bytes, capped at that burst; a pass larger than the burst is not
+	// slowed any further, so the bytes a single pass moves are not held to
+	// this rate. Zero disables the bucket.
+	IOBytesPerSecond int64
+
+	// IdleHint, when set, is consulted before every compaction pass; a
+	// false return postpones compaction until the next tick. It lets
+	// operators wire in a CPU-load sample without the storage package
+	// knowing anything about how load is measured.
+	IdleHint func() bool
+}
+
+func (c *CompactorConfig) sanitize() {
+	if c.Interval <= 0 {
+		c.Interval = DefaultCompactionInterval
+	}
+	if c.FragmentationThreshold <= 0 {
+		c.FragmentationThreshold = DefaultFragmentationThreshold
+	}
+}
+
+// CompactionStats is a point-in-time snapshot of the Compactor's counters,
+// shaped to be scraped directly into Prometheus gauges/counters by callers.
+type CompactionStats struct {
+	CompactionsTotal int64
+	BytesMoved       int64
+	TableCount       int64
+	PurgeTotal       int64
+}
+
+// Compactor runs CompactTables in the background on behalf of a Storage,
+// replacing the old pattern of every caller spawning its own
+// `go compaction()` loop whenever Put/Delete returned ErrFragmented.
+type Compactor struct {
+	storage *Storage
+	config  CompactorConfig
+	limiter *rate.Limiter
+
+	compactionsTotal int64
+	bytesMoved       int64
+	purgeTotal       int64
+
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	started bool
+}
+
+// NewCompactor creates a Compactor for s. Call Start to begin its
+// background loop.
+func NewCompactor(s *Storage, config CompactorConfig) *Compactor {
+	config.sanitize()
+
+	var limiter *rate.Limiter
+	if config.IOBytesPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(config.IOBytesPerSecond), int(config.IOBytesPerSecond))
+	}
+
+	return &Compactor{
+		storage: s,
+		config:  config,
+		limiter: limiter,
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+}
+
+// Start begins the background compaction loop. It is safe to call Stop
+// even if Start was never called.
+func (c *Compactor) Start() {
+	c.started = true
+	go c.run()
+}
+
+func (c *Compactor) run() {
+	defer close(c.doneCh)
+
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.tick()
+		}
+	}
+}
+
+func (c *Compactor) tick() {
+	if c.config.IdleHint != nil && !c.config.IdleHint() {
+		return
+	}
+	if !c.needsCompaction() {
+		return
+	}
+
+	before := c.storage.Len()
+	bytesBefore := c.storage.totalAllocated()
+
+	if c.limiter != nil {
+		// Wait for tokens covering the allocated bytes, but never more
+		// than the bucket's burst (one second's worth), in a single wait.
+		n := int(bytesBefore)
+		if burst := c.limiter.Burst(); n > burst {
+			n = burst
+		}
+		_ = c.limiter.WaitN(context.Background(), n)
+	}
+
+	// CompactTables always finishes in one pass today, but looping until it
+	// reports done keeps this call site correct if a future, incremental
+	// compactor implementation starts returning false mid-compaction.
+	for !c.storage.CompactTables() {
+	}
+
+	// Compaction copies only live records, so after a pass the bytes in
+	// use are the bytes that were moved.
+	var moved int64
+	c.storage.mu.RLock()
+	for _, t := range c.storage.tables {
+		moved += int64(t.inuse)
+	}
+	c.storage.mu.RUnlock()
+
+	atomic.AddInt64(&c.compactionsTotal, 1)
+	atomic.AddInt64(&c.bytesMoved, moved)
+	if c.storage.Len() < before {
+		atomic.AddInt64(&c.purgeTotal, int64(before-c.storage.Len()))
+	}
+}
+
+func (c *Compactor) needsCompaction() bool {
+	c.storage.mu.RLock()
+	defer c.storage.mu.RUnlock()
+
+	if len(c.storage.tables) > 1 {
+		return true
+	}
+	for _, t := range c.storage.tables {
+		if float64(t.garbage)/float64(t.allocated) >= c.config.FragmentationThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns a snapshot of the Compactor's counters, suitable for
+// Prometheus metrics such as olric_storage_compactions_total,
+// compaction_bytes_moved and purge_total.
+func (c *Compactor) Stats() CompactionStats {
+	c.storage.mu.RLock()
+	tableCount := int64(len(c.storage.tables))
+	c.storage.mu.RUnlock()
+
+	return CompactionStats{
+		CompactionsTotal: atomic.LoadInt64(&c.compactionsTotal),
+		BytesMoved:       atomic.LoadInt64(&c.bytesMoved),
+		TableCount:       tableCount,
+		PurgeTotal:       atomic.LoadInt64(&c.purgeTotal),
+	}
+}
+
+// Stop ends the background loop and waits for it to exit.
+func (c *Compactor) Stop() {
+	close(c.stopCh)
+	if c.started {
+		<-c.doneCh
+	}
+}
+
+func (s *Storage) totalAllocated() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total int64
+	for _, t := range s.tables {
+		total += int64(t.allocated)
+	}
+	return total
+}
+
+// Stats returns a point-in-time view of s suitable for metrics scraping.
+// TableCount reflects fragmentation pressure even when no Compactor is
+// running.
+func (s *Storage) Stats() CompactionStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return CompactionStats{
+		TableCount: int64(len(s.tables)),
+	}
+}
+
@@ -0,0 +1,115 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash"
+)
+
+func TestCompactor_RunsInBackground(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 1500; i++ {
+		hkey := xxhash.Sum64([]byte(bkey(i)))
+		vdata := &VData{Key: bkey(i), TTL: int64(i), Value: []byte(fmt.Sprintf("%01000d", i))}
+		_ = s.Put(hkey, vdata)
+	}
+	for i := 0; i < 1500; i++ {
+		hkey := xxhash.Sum64([]byte(bkey(i)))
+		_ = s.Delete(hkey)
+	}
+
+	c := NewCompactor(s, CompactorConfig{Interval: 20 * time.Millisecond})
+	c.Start()
+	defer c.Stop()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case <-deadline:
+			t.Fatal("compactor did not compact within the deadline")
+		default:
+		}
+		if c.Stats().CompactionsTotal > 0 && s.Stats().TableCount == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCompactor_IdleHintPostponesCompaction(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 1500; i++ {
+		hkey := xxhash.Sum64([]byte(bkey(i)))
+		vdata := &VData{Key: bkey(i), TTL: int64(i), Value: []byte(fmt.Sprintf("%01000d", i))}
+		_ = s.Put(hkey, vdata)
+	}
+
+	c := NewCompactor(s, CompactorConfig{
+		Interval: 10 * time.Millisecond,
+		IdleHint: func() bool { return false },
+	})
+	c.Start()
+	defer c.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	if c.Stats().CompactionsTotal != 0 {
+		t.Fatal("Expected no compactions while IdleHint reports busy")
+	}
+}
+
+func TestCompactor_ConcurrentForegroundTraffic(t *testing.T) {
+	s := New(0)
+	c := NewCompactor(s, CompactorConfig{Interval: 5 * time.Millisecond})
+	c.Start()
+	defer c.Stop()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		i := 0
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			hkey := xxhash.Sum64([]byte(bkey(i)))
+			vdata := &VData{Key: bkey(i), TTL: int64(i), Value: []byte(fmt.Sprintf("%01000d", i))}
+			_ = s.Put(hkey, vdata)
+			_, _ = s.Get(hkey)
+			_ = s.Delete(hkey)
+			_ = s.Len()
+			i++
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
+
+func TestStorage_Stats(t *testing.T) {
+	s := New(0)
+	if s.Stats().TableCount != 1 {
+		t.Fatalf("Expected 1 table. Got %d", s.Stats().TableCount)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import "math/bits"
+
+// cuckooBucketSize is the number of fingerprint slots per bucket. 4 is the
+// standard choice from the original cuckoo filter paper: it gets close to
+// 95% load factor before insertion starts failing, without the scan-per-
+// lookup cost of larger buckets.
+const cuckooBucketSize = 4
+
+// cuckooFingerprintMask keeps fingerprints at 12 bits (values 1..4095, 0 is reserved
+// for "empty") which the paper shows is enough headroom for a target false
+// positive rate around 3% at this bucket size.
+const cuckooFingerprintMask = 0x0FFF
+
+// cuckooMaxKicks bounds how many times Insert will evict and relocate an
+// existing fingerprint before giving up and reporting the filter full.
+const cuckooMaxKicks = 500
+
+// cuckooFilter is a per-table index that answers "definitely not present"
+// in O(1) without touching the table's hkeys map, so Check/Get can skip
+// tables a miss can never be in. Unlike a bloom filter, entries can be
+// removed without risking false negatives for keys that remain.
+type cuckooFilter struct {
+	buckets [][cuckooBucketSize]uint16
+	mask    uint64
+	count   int
+}
+
+// newCuckooFilter sizes a filter for at least capacity entries. The actual
+// bucket count is rounded up to a power of two so bucket indices can be
+// masked instead of computed with a modulo.
+func newCuckooFilter(capacity int) *cuckooFilter {
+	buckets := (capacity + cuckooBucketSize - 1) / cuckooBucketSize
+	if buckets < 16 {
+		buckets = 16
+	}
+	buckets = 1 << bits.Len(uint(buckets-1))
+
+	return &cuckooFilter{
+		buckets: make([][cuckooBucketSize]uint16, buckets),
+		mask:    uint64(buckets - 1),
+	}
+}
+
+// fingerprintOf derives a non-zero 12-bit fingerprint from hkey. hkey is
+// already a good-quality hash (xxhash of the original key), so the high
+// bits are reused directly rather than re-hashing.
+func fingerprintOf(hkey uint64) uint16 {
+	fp := uint16(hkey>>52) & cuckooFingerprintMask
+	if fp == 0 {
+		fp = 1
+	}
+	return fp
+}
+
+func (f *cuckooFilter) index1(hkey uint64) uint64 {
+	return hkey & f.mask
+}
+
+// index2 is the "partial-key cuckoo hashing" trick from the paper: XORing
+// the primary bucket index with a hash of the fingerprint gives the
+// alternate bucket, and applying the same XOR again recovers the first
+// index, so Delete/Insert never need the original hkey to relocate an
+// entry.
+func (f *cuckooFilter) index2(i1 uint64, fp uint16) uint64 {
+	return (i1 ^ fingerprintHash(fp)) & f.mask
+}
+
+func fingerprintHash(fp uint16) uint64 {
+	h := uint64(fp) * 0x9E3779B97F4A7C15
+	return h ^ (h >> 29)
+}
+
+// Insert adds hkey's fingerprint to the filter, relocating existing entries
+// (the cuckoo "kick" step) if both candidate buckets are full. It reports
+// false if the filter is too full to place the new entry, in which case
+// the caller should treat the filter as exhausted and fall back to always
+// checking the table directly.
+func (f *cuckooFilter) Insert(hkey uint64) bool {
+	fp := fingerprintOf(hkey)
+	i1 := f.index1(hkey)
+	i2 := f.index2(i1, fp)
+
+	if f.insertInto(i1, fp) || f.insertInto(i2, fp) {
+		f.count++
+		return true
+	}
+
+	// Both candidate buckets are full: evict the fingerprint in slot
+	// n%cuckooBucketSize of the current bucket and keep relocating it until
+	// a free slot turns up.
+	i := i1
+	for n := 0; n < cuckooMaxKicks; n++ {
+		slot := n % cuckooBucketSize
+		evicted := f.buckets[i][slot]
+		f.buckets[i][slot] = fp
+		fp = evicted
+		i = f.index2(i, fp)
+		if f.insertInto(i, fp) {
+			f.count++
+			return true
+		}
+	}
+	return false
+}
+
+func (f *cuckooFilter) insertInto(i uint64, fp uint16) bool {
+	bucket := &f.buckets[i]
+	for s := 0; s < cuckooBucketSize; s++ {
+		if bucket[s] == 0 {
+			bucket[s] = fp
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether hkey might be present. A true result can be a
+// false positive (bounded by the table's fingerprint width and load
+// factor); a false result is never wrong.
+func (f *cuckooFilter) Contains(hkey uint64) bool {
+	fp := fingerprintOf(hkey)
+	i1 := f.index1(hkey)
+	i2 := f.index2(i1, fp)
+	return f.bucketHas(i1, fp) || f.bucketHas(i2, fp)
+}
+
+func (f *cuckooFilter) bucketHas(i uint64, fp uint16) bool {
+	bucket := &f.buckets[i]
+	for s := 0; s < cuckooBucketSize; s++ {
+		if bucket[s] == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete removes one occurrence of hkey's fingerprint, if present. Unlike a
+// bloom filter, this cannot turn a false negative for any other key: each
+// fingerprint slot maps back to exactly the keys that were inserted with
+// it, and Delete only clears a slot that matches.
+func (f *cuckooFilter) Delete(hkey uint64) bool {
+	fp := fingerprintOf(hkey)
+	i1 := f.index1(hkey)
+	i2 := f.index2(i1, fp)
+	if f.deleteFrom(i1, fp) || f.deleteFrom(i2, fp) {
+		f.count--
+		return true
+	}
+	return false
+}
+
+func (f *cuckooFilter) deleteFrom(i uint64, fp uint16) bool {
+	bucket := &f.buckets[i]
+	for s := 0; s < cuckooBucketSize; s++ {
+		if bucket[s] == fp {
+			bucket[s] = 0
+			return true
+		}
+	}
+	return false
+}
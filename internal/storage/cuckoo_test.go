@@ -0,0 +1,116 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cespare/xxhash"
+)
+
+// cuckooTestKey derives an hkey the same way production code does (hashing
+// a key string), rather than using small sequential integers directly.
+// fingerprintOf only looks at bits 52-63 of hkey, so sequential integers
+// all collapse to the same fingerprint and never exercise the kick/
+// relocation path the way real, hashed hkeys do.
+func cuckooTestKey(i int) uint64 {
+	return xxhash.Sum64([]byte(bkey(i)))
+}
+
+func Test_CuckooFilter_InsertContains(t *testing.T) {
+	f := newCuckooFilter(1000)
+	for i := 0; i < 500; i++ {
+		if !f.Insert(cuckooTestKey(i)) {
+			t.Fatalf("Expected Insert to succeed for %d", i)
+		}
+	}
+	for i := 0; i < 500; i++ {
+		if !f.Contains(cuckooTestKey(i)) {
+			t.Fatalf("Expected Contains(%d) to be true", i)
+		}
+	}
+}
+
+func Test_CuckooFilter_Delete(t *testing.T) {
+	f := newCuckooFilter(1000)
+	key := cuckooTestKey(42)
+	f.Insert(key)
+	if !f.Contains(key) {
+		t.Fatal("Expected Contains(42) to be true after Insert")
+	}
+	if !f.Delete(key) {
+		t.Fatal("Expected Delete(42) to succeed")
+	}
+	if f.Contains(key) {
+		t.Fatal("Expected Contains(42) to be false after Delete")
+	}
+}
+
+func Test_CuckooFilter_DeleteDoesNotAffectOtherKeys(t *testing.T) {
+	f := newCuckooFilter(1000)
+	for i := 0; i < 200; i++ {
+		f.Insert(cuckooTestKey(i))
+	}
+	f.Delete(cuckooTestKey(100))
+	for i := 0; i < 200; i++ {
+		if i == 100 {
+			continue
+		}
+		if !f.Contains(cuckooTestKey(i)) {
+			t.Fatalf("Deleting one key should not evict %d", i)
+		}
+	}
+}
+
+func Test_Storage_Check_SkipsTablesViaFilter(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 100; i++ {
+		hkey := cuckooTestKey(i)
+		if err := s.Put(hkey, &VData{Key: bkey(i), TTL: int64(i), Value: bval(i)}); err != nil {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if !s.Check(cuckooTestKey(i)) {
+			t.Fatalf("Expected Check(%d) to be true", i)
+		}
+	}
+	// Keys that were never inserted should (almost always) be rejected by
+	// the filter before even touching the hkeys map; false positives are
+	// possible but Check must still never return a false negative for a
+	// key that is actually present, which the loop above already covers.
+	for i := 1000; i < 1010; i++ {
+		_ = s.Check(cuckooTestKey(i))
+	}
+}
+
+func Test_Storage_RebuildFilters(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 50; i++ {
+		hkey := cuckooTestKey(i)
+		if err := s.Put(hkey, &VData{Key: bkey(i), TTL: int64(i), Value: bval(i)}); err != nil {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+	}
+
+	s.RebuildFilters()
+
+	for i := 0; i < 50; i++ {
+		if !s.Check(cuckooTestKey(i)) {
+			t.Fatalf("Expected Check(%d) to be true after RebuildFilters", i)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Engine is implemented by every storage backend a DMap fragment can be
+// configured with. Storage (in-memory) is the default; BadgerEngine and
+// EtcdEngine trade some latency for spilling beyond RAM or for durability
+// shared across the cluster.
+type Engine interface {
+	// Name returns the engine identifier used in configuration, e.g.
+	// "kvstore", "badger" or "etcd".
+	Name() string
+
+	Put(hkey uint64, vdata *VData) error
+	Get(hkey uint64) (*VData, error)
+	Delete(hkey uint64) error
+	Check(hkey uint64) bool
+	Len() int
+	Range(f func(hkey uint64, vdata *VData) bool)
+
+	// Export streams a checksum-verified snapshot to w, used by the
+	// rebalance code path to move a partition without materializing it
+	// fully in memory first.
+	Export(w io.Writer) error
+
+	Close() error
+}
+
+// EngineConfig carries the per-engine configuration selected on a DMap. Name
+// picks the driver; the driver-specific fields are ignored by the other
+// drivers.
+type EngineConfig struct {
+	// Name selects the engine: "kvstore" (default), "badger" or "etcd".
+	Name string
+
+	// Badger holds configuration for the embedded LSM-tree engine.
+	Badger *BadgerConfig
+
+	// Etcd holds configuration for the cluster-shared etcd engine.
+	Etcd *EtcdConfig
+}
+
+// EngineFactory builds a new Engine instance from an EngineConfig. Drivers
+// register themselves via RegisterEngine during init().
+type EngineFactory func(c *EngineConfig) (Engine, error)
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]EngineFactory)
+)
+
+// RegisterEngine makes an Engine implementation available under name. It
+// panics if another engine is already registered under the same name, which
+// can only happen by programmer error (two drivers using the same Name()).
+func RegisterEngine(name string, factory EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if _, ok := engines[name]; ok {
+		panic(fmt.Sprintf("storage: engine %q is already registered", name))
+	}
+	engines[name] = factory
+}
+
+// NewEngine builds the Engine selected by c.Name. The zero value of
+// EngineConfig selects the default in-memory engine.
+func NewEngine(c *EngineConfig) (Engine, error) {
+	if c == nil || c.Name == "" {
+		return New(0), nil
+	}
+
+	enginesMu.RLock()
+	factory, ok := engines[c.Name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("storage: unknown engine %q", c.Name)
+	}
+	return factory(c)
+}
+
+func init() {
+	RegisterEngine("kvstore", func(c *EngineConfig) (Engine, error) {
+		return New(0), nil
+	})
+}
+
+// exportEngine produces the same framed snapshot format Storage.Export
+// writes, by streaming the engine through Range. Drivers that cannot offer
+// a cheaper native snapshot (BadgerEngine, EtcdEngine) use this so that
+// Export/Import stay portable across engines.
+func exportEngine(e Engine, w io.Writer) error {
+	return ExportTo(e, w, ExportOptions{})
+}
+
+// ImportInto rebuilds engine state from a snapshot previously produced by
+// Export/exportEngine, regardless of which engine originally wrote it.
+func ImportInto(e Engine, r io.Reader) error {
+	tmp, err := ImportFrom(r)
+	if err != nil {
+		return err
+	}
+	var putErr error
+	tmp.Range(func(hkey uint64, vdata *VData) bool {
+		// ErrFragmented only asks for a compaction; the record was stored.
+		if err := e.Put(hkey, vdata); err != nil && err != ErrFragmented {
+			putErr = err
+			return false
+		}
+		return true
+	})
+	return putErr
+}
@@ -0,0 +1,66 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_NewEngine_Default(t *testing.T) {
+	e, err := NewEngine(nil)
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if e.Name() != "kvstore" {
+		t.Fatalf("Expected kvstore. Got %s", e.Name())
+	}
+}
+
+func Test_NewEngine_Unknown(t *testing.T) {
+	_, err := NewEngine(&EngineConfig{Name: "does-not-exist"})
+	if err == nil {
+		t.Fatal("Expected an error for an unregistered engine")
+	}
+}
+
+func Test_NewEngine_ExportImportRoundtrip(t *testing.T) {
+	e, err := NewEngine(&EngineConfig{Name: "kvstore"})
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		vdata := &VData{Key: bkey(i), TTL: int64(i), Value: bval(i)}
+		if err := e.Put(uint64(i), vdata); err != nil {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := e.Export(&buf); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	fresh, err := NewEngine(&EngineConfig{Name: "kvstore"})
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if err := ImportInto(fresh, &buf); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if fresh.Len() != 10 {
+		t.Fatalf("Expected length: 10. Got: %d", fresh.Len())
+	}
+}
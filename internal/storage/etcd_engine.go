@@ -0,0 +1,188 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EtcdConfig configures the etcd-backed engine used for partitions that
+// need durability shared across the whole cluster rather than per-node
+// replication.
+type EtcdConfig struct {
+	// Endpoints lists the etcd cluster members, e.g. "localhost:2379".
+	Endpoints []string
+
+	// Prefix namespaces this engine's keys within the etcd keyspace, so
+	// multiple partition fragments can share one etcd cluster.
+	Prefix string
+
+	// DialTimeout bounds how long NewEtcdEngine waits to reach the cluster.
+	DialTimeout time.Duration
+
+	// RequestTimeout bounds every individual Put/Get/Delete call.
+	RequestTimeout time.Duration
+}
+
+// EtcdEngine is an Engine backed by an etcd v3 cluster. Every write goes
+// through Raft consensus, trading latency for durability that survives the
+// loss of the owning node.
+type EtcdEngine struct {
+	client  *clientv3.Client
+	prefix  string
+	timeout time.Duration
+}
+
+// NewEtcdEngine connects to the etcd cluster described by c.Etcd.
+func NewEtcdEngine(c *EngineConfig) (Engine, error) {
+	if c.Etcd == nil {
+		return nil, fmt.Errorf("storage: etcd engine requires EngineConfig.Etcd")
+	}
+
+	dialTimeout := c.Etcd.DialTimeout
+	if dialTimeout == 0 {
+		dialTimeout = 5 * time.Second
+	}
+	requestTimeout := c.Etcd.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = 2 * time.Second
+	}
+
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   c.Etcd.Endpoints,
+		DialTimeout: dialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to connect to etcd: %w", err)
+	}
+	return &EtcdEngine{
+		client:  cli,
+		prefix:  c.Etcd.Prefix,
+		timeout: requestTimeout,
+	}, nil
+}
+
+// Name implements Engine.
+func (e *EtcdEngine) Name() string {
+	return "etcd"
+}
+
+// etcdKey must be safe for use as a key in etcd's keyspace, so hkeys are
+// base64-encoded rather than written as raw bytes.
+func (e *EtcdEngine) etcdKey(hkey uint64) string {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], hkey)
+	return e.prefix + base64.RawURLEncoding.EncodeToString(buf[:])
+}
+
+func (e *EtcdEngine) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), e.timeout)
+}
+
+// Put implements Engine.
+func (e *EtcdEngine) Put(hkey uint64, vdata *VData) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	raw := encodeVData(vdata)
+	_, err := e.client.Put(ctx, e.etcdKey(hkey), string(raw))
+	return err
+}
+
+// Get implements Engine.
+func (e *EtcdEngine) Get(hkey uint64) (*VData, error) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	resp, err := e.client.Get(ctx, e.etcdKey(hkey))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return decodeVData(resp.Kvs[0].Value), nil
+}
+
+// Check implements Engine.
+func (e *EtcdEngine) Check(hkey uint64) bool {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	resp, err := e.client.Get(ctx, e.etcdKey(hkey), clientv3.WithCountOnly())
+	return err == nil && resp.Count > 0
+}
+
+// Delete implements Engine.
+func (e *EtcdEngine) Delete(hkey uint64) error {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	_, err := e.client.Delete(ctx, e.etcdKey(hkey))
+	return err
+}
+
+// Len implements Engine.
+func (e *EtcdEngine) Len() int {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	resp, err := e.client.Get(ctx, e.prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0
+	}
+	return int(resp.Count)
+}
+
+// Range implements Engine.
+func (e *EtcdEngine) Range(f func(hkey uint64, vdata *VData) bool) {
+	ctx, cancel := e.ctx()
+	defer cancel()
+	resp, err := e.client.Get(ctx, e.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return
+	}
+	for _, kv := range resp.Kvs {
+		raw, err := base64.RawURLEncoding.DecodeString(string(kv.Key[len(e.prefix):]))
+		if err != nil || len(raw) != 8 {
+			continue
+		}
+		hkey := binary.BigEndian.Uint64(raw)
+		if !f(hkey, decodeVData(kv.Value)) {
+			return
+		}
+	}
+}
+
+// Export implements Engine by streaming Range, the same as BadgerEngine.
+func (e *EtcdEngine) Export(w io.Writer) error {
+	return exportEngine(e, w)
+}
+
+// Close implements Engine.
+func (e *EtcdEngine) Close() error {
+	return e.client.Close()
+}
+
+// Interface guard
+var _ Engine = (*EtcdEngine)(nil)
+
+func init() {
+	RegisterEngine("etcd", func(c *EngineConfig) (Engine, error) {
+		return NewEtcdEngine(c)
+	})
+}
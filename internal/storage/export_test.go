@@ -0,0 +1,127 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cespare/xxhash"
+)
+
+func TestExportTo_RoundTrip(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 50; i++ {
+		hkey := xxhash.Sum64([]byte(bkey(i)))
+		vdata := &VData{Key: bkey(i), TTL: int64(i), Value: bval(i)}
+		if err := s.Put(hkey, vdata); err != nil && err != ErrFragmented {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTo(s, &buf, ExportOptions{}); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	if err := VerifyChecksum(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	fresh, err := ImportFrom(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if fresh.Len() != 50 {
+		t.Fatalf("Expected length: 50. Got: %d", fresh.Len())
+	}
+	for i := 0; i < 50; i++ {
+		hkey := xxhash.Sum64([]byte(bkey(i)))
+		vdata, err := fresh.Get(hkey)
+		if err != nil {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+		if vdata.Key != bkey(i) {
+			t.Fatalf("Expected %s. Got %s", bkey(i), vdata.Key)
+		}
+	}
+}
+
+func TestExportTo_Compressed(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 20; i++ {
+		hkey := xxhash.Sum64([]byte(bkey(i)))
+		vdata := &VData{Key: bkey(i), TTL: int64(i), Value: bval(i)}
+		if err := s.Put(hkey, vdata); err != nil && err != ErrFragmented {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTo(s, &buf, ExportOptions{Compress: true}); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	fresh, err := ImportFrom(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if fresh.Len() != 20 {
+		t.Fatalf("Expected length: 20. Got: %d", fresh.Len())
+	}
+}
+
+func TestExportRange(t *testing.T) {
+	s := New(0)
+	for i := 0; i < 100; i++ {
+		hkey := uint64(i)
+		vdata := &VData{Key: bkey(i), TTL: int64(i), Value: bval(i)}
+		if err := s.Put(hkey, vdata); err != nil && err != ErrFragmented {
+			t.Fatalf("Expected nil. Got %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ExportRange(s, &buf, 10, 20); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	fresh, err := ImportFrom(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	if fresh.Len() != 10 {
+		t.Fatalf("Expected length: 10. Got: %d", fresh.Len())
+	}
+}
+
+func TestVerifyChecksum_DetectsCorruption(t *testing.T) {
+	s := New(0)
+	hkey := xxhash.Sum64([]byte(bkey(1)))
+	if err := s.Put(hkey, &VData{Key: bkey(1), TTL: 1, Value: bval(1)}); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTo(s, &buf, ExportOptions{}); err != nil {
+		t.Fatalf("Expected nil. Got %v", err)
+	}
+	corrupted := buf.Bytes()
+	corrupted[len(corrupted)-1] ^= 0xFF
+
+	if err := VerifyChecksum(bytes.NewReader(corrupted)); err == nil {
+		t.Fatal("Expected a checksum error for corrupted data")
+	}
+}
@@ -0,0 +1,386 @@
+// Copyright 2018-2025 The Olric Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storage implements a simple, append-only key/value store which is
+// used to hold the data of a single partition fragment. It is optimized for
+// the DMap use case: small values, a high put/get ratio and the ability to
+// reclaim space from deleted/expired entries via compaction.
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// minimumSize is the default table size. A new table is allocated with this
+// size and grows by doubling whenever it runs out of free space.
+const minimumSize = 1 << 20 // 1MB
+
+// maxGarbageRatio is the fraction of a table that may be garbage (deleted or
+// overwritten entries) before Put/Delete report ErrFragmented so the caller
+// schedules a compaction.
+const maxGarbageRatio = 0.40
+
+var (
+	// ErrKeyNotFound is returned when a key could not be found in the storage.
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrFragmented is returned by Put/Delete when the storage has too much
+	// garbage or too many tables and the caller should run CompactTables.
+	ErrFragmented = errors.New("storage is fragmented")
+)
+
+// VData represents a value and its metadata stored against a hash key.
+type VData struct {
+	Key   string
+	TTL   int64
+	Value []byte
+}
+
+// recordHeaderSize is the fixed-size header written before every record:
+// 8 bytes TTL, 4 bytes key length, 4 bytes value length.
+const recordHeaderSize = 16
+
+func encodeVData(vdata *VData) []byte {
+	buf := make([]byte, recordHeaderSize+len(vdata.Key)+len(vdata.Value))
+	binary.BigEndian.PutUint64(buf[0:8], uint64(vdata.TTL))
+	binary.BigEndian.PutUint32(buf[8:12], uint32(len(vdata.Key)))
+	binary.BigEndian.PutUint32(buf[12:16], uint32(len(vdata.Value)))
+	copy(buf[16:16+len(vdata.Key)], vdata.Key)
+	copy(buf[16+len(vdata.Key):], vdata.Value)
+	return buf
+}
+
+func decodeVData(raw []byte) *VData {
+	ttl := int64(binary.BigEndian.Uint64(raw[0:8]))
+	keyLen := int(binary.BigEndian.Uint32(raw[8:12]))
+	valueLen := int(binary.BigEndian.Uint32(raw[12:16]))
+	key := string(raw[16 : 16+keyLen])
+	value := raw[16+keyLen : 16+keyLen+valueLen]
+	return &VData{Key: key, TTL: ttl, Value: value}
+}
+
+func recordSize(raw []byte) int {
+	keyLen := int(binary.BigEndian.Uint32(raw[8:12]))
+	valueLen := int(binary.BigEndian.Uint32(raw[12:16]))
+	return recordHeaderSize + keyLen + valueLen
+}
+
+// table is a flat, append-only byte buffer. Records are appended at offset
+// and never moved in place; Delete only removes the hkey from the index so
+// the space can be reclaimed by compaction.
+type table struct {
+	memory    []byte
+	hkeys     map[uint64]int
+	offset    int
+	allocated int
+	inuse     int
+	garbage   int
+
+	// filter accelerates negative lookups: Check/Get consult it before
+	// touching hkeys, so a miss against an old, mostly-garbage table costs
+	// one cuckoo probe instead of a hash-map lookup. It is rebuilt from
+	// scratch by CompactTables and RebuildFilters, and kept in sync by
+	// putRaw/delete in between. filterFull is set once Insert reports the
+	// filter is out of room; from then on this table's filter is treated
+	// as always-positive (i.e. ignored) until the next rebuild.
+	filter     *cuckooFilter
+	filterFull bool
+}
+
+// estimatedMinRecordSize seeds the cuckoo filter's capacity estimate: one
+// filter slot per 32 bytes of table. It is twice recordHeaderSize, so a
+// table holding mostly records smaller than 32 bytes can fill its filter
+// before the table itself is full; filterFull then takes over.
+const estimatedMinRecordSize = 32
+
+func newTable(size int) *table {
+	if size < minimumSize {
+		size = minimumSize
+	}
+	return &table{
+		memory:    make([]byte, size),
+		hkeys:     make(map[uint64]int),
+		allocated: size,
+		filter:    newCuckooFilter(size / estimatedMinRecordSize),
+	}
+}
+
+func (t *table) putRaw(hkey uint64, raw []byte) error {
+	if t.offset+len(raw) > t.allocated {
+		return ErrFragmented
+	}
+	if old, ok := t.hkeys[hkey]; ok {
+		t.garbage += recordSize(t.memory[old:])
+		t.inuse -= recordSize(t.memory[old:])
+	} else if !t.filterFull && !t.filter.Insert(hkey) {
+		t.filterFull = true
+	}
+	t.hkeys[hkey] = t.offset
+	copy(t.memory[t.offset:], raw)
+	t.offset += len(raw)
+	t.inuse += len(raw)
+	return nil
+}
+
+// mayContain is a cheap pre-check Check/Get use to skip a table the hkey
+// can never be in. It is conservative: once the filter has overflowed
+// (filterFull), the table is assumed to possibly contain hkey, falling
+// back to the old O(tables) behavior for just that table.
+func (t *table) mayContain(hkey uint64) bool {
+	return t.filterFull || t.filter.Contains(hkey)
+}
+
+func (t *table) get(hkey uint64) (*VData, bool) {
+	if !t.mayContain(hkey) {
+		return nil, false
+	}
+	offset, ok := t.hkeys[hkey]
+	if !ok {
+		return nil, false
+	}
+	return decodeVData(t.memory[offset:]), true
+}
+
+func (t *table) delete(hkey uint64) bool {
+	offset, ok := t.hkeys[hkey]
+	if !ok {
+		return false
+	}
+	delete(t.hkeys, hkey)
+	if !t.filterFull {
+		t.filter.Delete(hkey)
+	}
+	size := recordSize(t.memory[offset:])
+	t.garbage += size
+	t.inuse -= size
+	return true
+}
+
+func (t *table) fragmented() bool {
+	return t.allocated > 0 && float64(t.garbage)/float64(t.allocated) > maxGarbageRatio
+}
+
+func (t *table) rangeRaw(f func(hkey uint64, raw []byte)) {
+	for hkey, offset := range t.hkeys {
+		raw := t.memory[offset:]
+		f(hkey, raw[:recordSize(raw)])
+	}
+}
+
+// Storage is the default, in-memory Engine implementation. It keeps its data
+// in a list of append-only tables and reclaims garbage via CompactTables.
+//
+// mu guards tables against the concurrent mutation every exported method
+// below performs, including CompactTables, which the background Compactor
+// calls from its own goroutine while Put/Get/Delete/Len may be running on
+// behalf of foreground traffic.
+type Storage struct {
+	mu     sync.RWMutex
+	tables []*table
+}
+
+// New creates a new, empty Storage. quota is kept for backward compatibility
+// with older call sites; the in-memory engine always starts with a single
+// minimumSize table and grows on demand.
+func New(quota int) *Storage {
+	return &Storage{
+		tables: []*table{newTable(minimumSize)},
+	}
+}
+
+// Name implements Engine.
+func (s *Storage) Name() string {
+	return "kvstore"
+}
+
+func (s *Storage) active() *table {
+	return s.tables[len(s.tables)-1]
+}
+
+// Put inserts or overwrites the value addressed by hkey.
+func (s *Storage) Put(hkey uint64, vdata *VData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw := encodeVData(vdata)
+	t := s.active()
+	if err := t.putRaw(hkey, raw); err != nil {
+		// Current table is full. Allocate a new, bigger one and retry there.
+		nt := newTable(t.allocated * 2)
+		s.tables = append(s.tables, nt)
+		if err := nt.putRaw(hkey, raw); err != nil {
+			return err
+		}
+		return ErrFragmented
+	}
+	if t.fragmented() {
+		return ErrFragmented
+	}
+	return nil
+}
+
+// Get retrieves the value addressed by hkey, searching from the most
+// recently written table to the oldest.
+func (s *Storage) Get(hkey uint64) (*VData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.tables) - 1; i >= 0; i-- {
+		if vdata, ok := s.tables[i].get(hkey); ok {
+			return vdata, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// Check reports whether hkey exists, without paying the cost of decoding
+// the stored value.
+func (s *Storage) Check(hkey uint64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.tables) - 1; i >= 0; i-- {
+		t := s.tables[i]
+		if !t.mayContain(hkey) {
+			continue
+		}
+		if _, ok := t.hkeys[hkey]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Delete removes the value addressed by hkey, if present.
+func (s *Storage) Delete(hkey uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fragmented bool
+	for _, t := range s.tables {
+		if t.delete(hkey) && t.fragmented() {
+			fragmented = true
+		}
+	}
+	if fragmented {
+		return ErrFragmented
+	}
+	return nil
+}
+
+// Len returns the total number of live keys across all tables.
+func (s *Storage) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total int
+	for _, t := range s.tables {
+		total += len(t.hkeys)
+	}
+	return total
+}
+
+// Range calls f for every live key/value pair. Range stops early if f
+// returns false.
+func (s *Storage) Range(f func(hkey uint64, vdata *VData) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.tables {
+		for hkey, offset := range t.hkeys {
+			if !f(hkey, decodeVData(t.memory[offset:])) {
+				return
+			}
+		}
+	}
+}
+
+// CompactTables merges live records from every table into a single, tightly
+// packed table, shrinking it back toward minimumSize once most of its
+// garbage has been reclaimed. It returns true once there is nothing left to
+// compact, so callers can loop until done, as shown in Test_CompactTables.
+func (s *Storage) CompactTables() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.tables) <= 1 && !s.tables[0].fragmented() {
+		return true
+	}
+
+	total := 0
+	for _, t := range s.tables {
+		total += t.inuse
+	}
+	size := minimumSize
+	for size < total {
+		size *= 2
+	}
+
+	fresh := newTable(size)
+	for _, t := range s.tables {
+		t.rangeRaw(func(hkey uint64, raw []byte) {
+			_ = fresh.putRaw(hkey, raw)
+		})
+	}
+	s.tables = []*table{fresh}
+	return true
+}
+
+// RebuildFilters discards and reconstructs every table's cuckoo filter from
+// its current hkeys. Import builds filters incrementally via Put, so this
+// is only needed after a code path that populates tables without going
+// through Put; it is exposed mainly so operators restoring a snapshot can
+// force the acceleration structures back into a known-good state.
+func (s *Storage) RebuildFilters() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tables {
+		t.filter = newCuckooFilter(t.allocated / estimatedMinRecordSize)
+		t.filterFull = false
+		for hkey := range t.hkeys {
+			if !t.filter.Insert(hkey) {
+				t.filterFull = true
+				break
+			}
+		}
+	}
+}
+
+// Export streams a checksum-verified snapshot of s to w, one record at a
+// time, so it scales to multi-GB partitions instead of buffering the whole
+// thing in memory. It's a thin wrapper around ExportTo with the default
+// ExportOptions.
+func (s *Storage) Export(w io.Writer) error {
+	return ExportTo(s, w, ExportOptions{})
+}
+
+// Import rebuilds a Storage by streaming a snapshot previously written by
+// Export from r, verifying every record's checksum as it goes. It's a thin
+// wrapper around ImportFrom.
+func Import(r io.Reader) (*Storage, error) {
+	return ImportFrom(r)
+}
+
+// Close releases any resources held by the storage. The in-memory engine
+// has nothing to release, but the method exists to satisfy Engine.
+func (s *Storage) Close() error {
+	return nil
+}
+
+// Interface guard
+var _ Engine = (*Storage)(nil)
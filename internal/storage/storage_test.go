@@ -267,11 +267,11 @@ func Test_ExportImport(t *testing.T) {
 			t.Fatalf("Expected nil. Got %v", err)
 		}
 	}
-	data, err := s.Export()
-	if err != nil {
+	var buf bytes.Buffer
+	if err := s.Export(&buf); err != nil {
 		t.Fatalf("Expected nil. Got %v", err)
 	}
-	fresh, err := Import(data)
+	fresh, err := Import(&buf)
 	if err != nil {
 		t.Fatalf("Expected nil. Got %v", err)
 	}